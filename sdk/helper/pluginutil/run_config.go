@@ -0,0 +1,110 @@
+package pluginutil
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+
+	log "github.com/hashicorp/go-hclog"
+	plugin "github.com/hashicorp/go-plugin"
+)
+
+// PluginMetadataModeEnv is the environment variable a plugin process reads
+// to learn it's being run in metadata mode, i.e. only to answer questions
+// about itself (its type, its version) rather than to serve real requests.
+const PluginMetadataModeEnv = "VAULT_PLUGIN_METADATA_MODE"
+
+// PluginDataEnv carries a plugin's startup config, base64-encoded, to the
+// plugin subprocess. It's re-set on every spawn from whatever the
+// registered DataSource currently returns, so a FileData source's edits
+// reach the plugin without it needing to read the file itself.
+const PluginDataEnv = "VAULT_PLUGIN_DATA"
+
+// runConfig accumulates the parameters needed to spawn a plugin process,
+// built up from a PluginRunner and a list of RunOpts.
+type runConfig struct {
+	runner *PluginRunner
+
+	runnerUtil     RunnerUtil
+	pluginSets     map[int]plugin.PluginSet
+	hs             plugin.HandshakeConfig
+	logger         log.Logger
+	isMetadataMode bool
+	autoMTLS       bool
+	env            []string
+	data           []byte
+}
+
+// RunOpt configures a runConfig. Options are applied in the order passed to
+// PluginRunner.RunConfig.
+type RunOpt func(*runConfig)
+
+func Runner(r RunnerUtil) RunOpt {
+	return func(rc *runConfig) { rc.runnerUtil = r }
+}
+
+func PluginSets(pluginSets map[int]plugin.PluginSet) RunOpt {
+	return func(rc *runConfig) { rc.pluginSets = pluginSets }
+}
+
+func HandshakeConfig(hs plugin.HandshakeConfig) RunOpt {
+	return func(rc *runConfig) { rc.hs = hs }
+}
+
+func Logger(logger log.Logger) RunOpt {
+	return func(rc *runConfig) { rc.logger = logger }
+}
+
+func MetadataMode(isMetadataMode bool) RunOpt {
+	return func(rc *runConfig) { rc.isMetadataMode = isMetadataMode }
+}
+
+func AutoMTLS(autoMTLS bool) RunOpt {
+	return func(rc *runConfig) { rc.autoMTLS = autoMTLS }
+}
+
+func Env(env ...string) RunOpt {
+	return func(rc *runConfig) { rc.env = append(rc.env, env...) }
+}
+
+// PluginData attaches the plugin's startup config, as resolved from its
+// DataSource, to the process about to be spawned. It's passed to the
+// plugin as an environment variable rather than an argument, so it never
+// shows up in a process listing.
+func PluginData(data []byte) RunOpt {
+	return func(rc *runConfig) { rc.data = data }
+}
+
+// run starts the plugin subprocess described by rc and returns the
+// go-plugin client managing its lifecycle.
+func (rc *runConfig) run(ctx context.Context) (*plugin.Client, error) {
+	if rc.runner.Builtin {
+		return nil, fmt.Errorf("cannot run builtin plugin %q as a subprocess", rc.runner.Name)
+	}
+
+	cmd := exec.Command(rc.runner.Command, rc.runner.Args...)
+	cmd.Env = append(cmd.Env, rc.runner.Env...)
+	cmd.Env = append(cmd.Env, rc.env...)
+	if rc.isMetadataMode {
+		cmd.Env = append(cmd.Env, PluginMetadataModeEnv+"=true")
+	}
+	if len(rc.data) > 0 {
+		cmd.Env = append(cmd.Env, PluginDataEnv+"="+base64.StdEncoding.EncodeToString(rc.data))
+	}
+
+	clientConfig := &plugin.ClientConfig{
+		HandshakeConfig:  rc.hs,
+		Plugins:          rc.pluginSets[0],
+		VersionedPlugins: rc.pluginSets,
+		Cmd:              cmd,
+		AutoMTLS:         rc.autoMTLS,
+		Logger:           rc.logger,
+		AllowedProtocols: []plugin.Protocol{
+			plugin.ProtocolNetRPC,
+			plugin.ProtocolGRPC,
+		},
+	}
+
+	return plugin.NewClient(clientConfig), nil
+}
@@ -0,0 +1,81 @@
+package pluginutil
+
+import (
+	"context"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+)
+
+// BuiltinFactory constructs an instance of a builtin plugin directly,
+// in-process, without going through go-plugin at all.
+type BuiltinFactory func() (interface{}, error)
+
+// RunnerUtil is the subset of *vault.Core a PluginRunner needs in order to
+// spawn a plugin process, kept narrow so tests can supply a fake rather
+// than standing up a full Core.
+type RunnerUtil interface {
+	MlockEnabled() bool
+}
+
+// PluginRunner is a catalog entry describing how to run a single plugin,
+// whether builtin (Builtin is true, BuiltinFactory constructs it in-process)
+// or external (Command/Args/Env/Sha256 describe the subprocess, and the
+// remaining fields describe how it's configured and gated).
+type PluginRunner struct {
+	Name    string            `json:"name"`
+	Type    consts.PluginType `json:"type"`
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     []string          `json:"env"`
+	Sha256  []byte            `json:"sha256"`
+
+	Builtin        bool           `json:"builtin"`
+	BuiltinFactory BuiltinFactory `json:"-"`
+
+	// Reference is the registry reference this plugin was last pulled
+	// from (e.g. via Pull or Upgrade), or empty for a plugin registered
+	// directly via Set/SetConfig.
+	Reference string `json:"reference,omitempty"`
+
+	// State is the plugin's current lifecycle state: registered, enabled,
+	// or disabled. See vault.PluginState for the possible values; it's
+	// stored here as a plain string so this package doesn't need to depend
+	// on the vault package that owns the PluginState type.
+	State string `json:"state,omitempty"`
+
+	// DataSourceKind and DataSourceRef identify the vault.DataSource this
+	// entry's startup config comes from. DataSourceRef is empty for a
+	// fixed data source (whose bytes live in Data instead) and holds the
+	// file path for a file-backed one.
+	DataSourceKind string `json:"data_source_kind,omitempty"`
+	DataSourceRef  string `json:"data_source_ref,omitempty"`
+
+	// Data holds the fixed data source's config bytes. It's only
+	// populated (and only meaningful) when DataSourceKind is "fixed" or
+	// empty; a file-backed source keeps its bytes on disk instead.
+	Data []byte `json:"data,omitempty"`
+
+	// Version is the plugin's informational semver version, if known.
+	Version string `json:"version,omitempty"`
+
+	// MinVersion and MaxVersion, if set, constrain the recorded Version above,
+	// checked each time the plugin process is spawned; the running plugin is
+	// not asked for its version.
+	MinVersion string `json:"min_version,omitempty"`
+	MaxVersion string `json:"max_version,omitempty"`
+}
+
+// RunConfig builds a runConfig from opts and starts the plugin process (or
+// returns an error without starting anything, if the runner describes a
+// builtin plugin, since those never spawn a subprocess).
+func (r *PluginRunner) RunConfig(ctx context.Context, opts ...RunOpt) (*plugin.Client, error) {
+	rc := &runConfig{
+		runner: r,
+	}
+	for _, opt := range opts {
+		opt(rc)
+	}
+
+	return rc.run(ctx)
+}
@@ -46,12 +46,37 @@ type PluginCatalog struct {
 	// routed to the existing plugin process.
 	multiplexedClients map[string]*MultiplexedClient
 
+	// registryClientOverride lets tests substitute a fake registryClient in
+	// place of the default HTTP client used by Pull/Push/Privileges.
+	registryClientOverride registryClient
+
+	// healthStatus holds the supervisor's last-known health record for each
+	// plugin with a live multiplexed client.
+	healthStatus map[string]*PluginHealth
+
+	// dispensedHandles tracks every DispensedHandle handed out per plugin
+	// name, so the supervisor can re-dispense them against a respawned
+	// plugin process.
+	dispensedHandles map[string][]*DispensedHandle
+
+	// healthSupervisorCancel stops the background health-check goroutine
+	// started by startHealthSupervisor. It's nil if the supervisor hasn't
+	// been started, or after Stop has already been called.
+	healthSupervisorCancel context.CancelFunc
+
 	lock sync.RWMutex
 }
 
 type MultiplexedClient struct {
 	logger log.Logger
 
+	// catalogLock is the owning PluginCatalog's lock, shared rather than
+	// copied so that Close (the one mutator called directly by consumers
+	// that hold a *MultiplexedClient, rather than by the catalog itself)
+	// still synchronizes its field writes against everything else that
+	// reads or writes this client's fields under c.lock.
+	catalogLock *sync.RWMutex
+
 	// id is the ID for this grpc connection
 	id string
 	// connectionCount is the number of databases associated with this connection
@@ -66,6 +91,25 @@ type MultiplexedClient struct {
 
 	// client handles the lifecycle of a plugin process
 	client *plugin.Client
+
+	// dataHash is the hash of the DataSource bytes this client was last
+	// started with, so Reload can tell whether a FileData source has
+	// changed since the plugin was spawned.
+	dataHash string
+
+	// spawnArgs records the parameters this client was started with, so the
+	// health supervisor can respawn the process after a failed Ping without
+	// needing a caller to supply them again.
+	spawnArgs *spawnArgs
+}
+
+// spawnArgs is the set of parameters needed to (re)spawn a plugin process,
+// captured at first spawn for use by the health supervisor.
+type spawnArgs struct {
+	sys            pluginutil.RunnerUtil
+	pluginRunner   *pluginutil.PluginRunner
+	namedLogger    log.Logger
+	isMetadataMode bool
 }
 
 func (m *MultiplexedClient) Protocol() plugin.ClientProtocol {
@@ -80,24 +124,63 @@ func (m *MultiplexedClient) ID() string {
 	return m.id
 }
 
+// Close releases the caller's connection to this plugin, killing the
+// underlying process once the last connection has gone. It snapshots the
+// fields it needs and releases catalogLock before calling protocol.Close
+// (rather than holding the lock across that call), for the same reason
+// checkAndRecover doesn't hold it across Ping: a blocking plugin RPC
+// shouldn't freeze every other catalog operation. The liveness check before
+// the final teardown below guards against a concurrent Close having already
+// torn this client down (and getPluginClient possibly having respawned it
+// onto a new protocol) while protocol.Close was in flight.
 func (m *MultiplexedClient) Close() error {
+	m.catalogLock.Lock()
 	m.connectionCount -= 1
 	m.logger.Debug("deleted multiplexedClients connection entry")
+	protocol := m.protocol
+	remaining := m.connectionCount
+	m.catalogLock.Unlock()
 
-	err := m.protocol.Close()
-	if err != nil {
+	if protocol == nil {
+		return nil
+	}
+
+	if err := protocol.Close(); err != nil {
 		return err
 	}
-	if m.connectionCount == 0 {
+
+	if remaining > 0 {
+		return nil
+	}
+
+	m.catalogLock.Lock()
+	defer m.catalogLock.Unlock()
+
+	if m.protocol != protocol {
+		return nil
+	}
+
+	if m.client != nil {
 		m.client.Kill()
-		m.client = nil
-		m.protocol = nil
-		m.clientConn = nil
-		m.logger.Debug("killed plugin process", "id", m.id, "name", m.name)
 	}
+	m.client = nil
+	m.protocol = nil
+	m.clientConn = nil
+	m.logger.Debug("killed plugin process", "id", m.id, "name", m.name)
 	return nil
 }
 
+// resetClient clears the fields spawnPluginProcess populates, after it has
+// killed the process they described. Without this, getPluginClient's
+// mpc.client == nil check would still see the (now-killed) client from a
+// failed spawn attempt and never try to respawn it, leaving the plugin
+// permanently wedged after one failed version check.
+func (m *MultiplexedClient) resetClient() {
+	m.client = nil
+	m.protocol = nil
+	m.clientConn = nil
+}
+
 func (m *MultiplexedClient) Dispense(name string) (interface{}, error) {
 	pluginInstance, err := m.protocol.Dispense(name)
 	if err != nil {
@@ -115,6 +198,13 @@ func (m *MultiplexedClient) Ping() error {
 }
 
 func (c *Core) setupPluginCatalog(ctx context.Context) error {
+	// setupPluginCatalog runs on every unseal. Stop the outgoing catalog's
+	// health supervisor first so its goroutine doesn't keep running,
+	// pinned to a PluginCatalog nothing references anymore.
+	if c.pluginCatalog != nil {
+		c.pluginCatalog.Stop()
+	}
+
 	c.pluginCatalog = &PluginCatalog{
 		builtinRegistry: c.builtinRegistry,
 		catalogView:     NewBarrierView(c.barrier, pluginCatalogPath),
@@ -132,9 +222,26 @@ func (c *Core) setupPluginCatalog(ctx context.Context) error {
 		c.logger.Info("successfully setup plugin catalog", "plugin-directory", c.pluginDirectory)
 	}
 
+	// The supervisor outlives the setup call, so it manages its own
+	// lifetime rather than being tied to ctx; teardownPluginCatalog stops
+	// it, and setupPluginCatalog stops the outgoing one above on the next
+	// unseal. teardownPluginCatalog must be called from preSeal for the
+	// former to actually happen on reseal; see its doc comment.
+	c.pluginCatalog.startHealthSupervisor()
+
 	return nil
 }
 
+// teardownPluginCatalog stops the plugin catalog's health supervisor. Core's
+// preSeal must call this, alongside its other per-subsystem teardown, or
+// the supervisor goroutine (and the plugin processes it keeps pinging and
+// respawning) outlives the sealed core that started it.
+func (c *Core) teardownPluginCatalog() {
+	if c.pluginCatalog != nil {
+		c.pluginCatalog.Stop()
+	}
+}
+
 func (c *PluginCatalog) getMultiplexedClient(pluginName string) *MultiplexedClient {
 	if mpc, ok := c.multiplexedClients[pluginName]; ok {
 		c.logger.Debug("MultiplexedClient exists", "pluginName", pluginName)
@@ -153,7 +260,7 @@ func (c *PluginCatalog) newMultiplexedClient(pluginName string) *MultiplexedClie
 		c.logger.Debug("created multiplexedClients map")
 	}
 
-	mpc := &MultiplexedClient{logger: c.logger}
+	mpc := &MultiplexedClient{logger: c.logger, catalogLock: &c.lock}
 
 	// set the MultiplexedClient for the given plugin name
 	c.multiplexedClients[pluginName] = mpc
@@ -174,49 +281,113 @@ func (c *PluginCatalog) GetPluginClient(ctx context.Context, sys pluginutil.Runn
 // getPluginClient returns a client for managing the lifecycle of a plugin
 // process
 func (c *PluginCatalog) getPluginClient(ctx context.Context, sys pluginutil.RunnerUtil, pluginRunner *pluginutil.PluginRunner, namedLogger log.Logger, isMetadataMode bool) (*MultiplexedClient, error) {
+	if !pluginRunner.Builtin {
+		switch PluginState(pluginRunner.State) {
+		case PluginStateEnabled:
+			// fine to spawn
+		case PluginStateDisabled:
+			return nil, ErrPluginDisabled
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrPluginNotEnabled, pluginRunner.Name)
+		}
+	}
+
 	mpc := c.getMultiplexedClient(pluginRunner.Name)
 
 	if mpc.client == nil {
-		c.logger.Debug("spawning a new plugin process")
-		client, err := pluginRunner.RunConfig(ctx,
-			pluginutil.Runner(sys),
-			pluginutil.PluginSets(v5.PluginSets),
-			pluginutil.HandshakeConfig(v5.HandshakeConfig),
-			pluginutil.Logger(namedLogger),
-			pluginutil.MetadataMode(isMetadataMode),
-			pluginutil.AutoMTLS(true),
-		)
-		if err != nil {
+		if err := c.spawnPluginProcess(ctx, mpc, sys, pluginRunner, namedLogger, isMetadataMode); err != nil {
 			return nil, err
 		}
+	}
+	mpc.connectionCount += 1
 
-		mpc.client = client
-		// Get the protocol client for this connection.
-		// Subsequent calls to this will return the same client.
-		rpcClient, err := mpc.client.Client()
-		if err != nil {
-			return nil, err
-		}
+	return mpc, nil
+}
 
-		// set the ClientProtocol connection for the given ID
-		mpc.protocol = rpcClient
+// spawnPluginProcess starts the plugin process for mpc and wires up its
+// protocol client. It also records the parameters used so the health
+// supervisor can respawn the process later with identical settings.
+func (c *PluginCatalog) spawnPluginProcess(ctx context.Context, mpc *MultiplexedClient, sys pluginutil.RunnerUtil, pluginRunner *pluginutil.PluginRunner, namedLogger log.Logger, isMetadataMode bool) error {
+	c.logger.Debug("spawning a new plugin process")
 
-		gc, ok := rpcClient.(*plugin.GRPCClient)
-		if ok {
-			mpc.clientConn = gc.Conn
-		}
+	// Re-verify the on-disk binary against the sha256 recorded at
+	// registration time, not just when it was registered: the file may
+	// have been swapped out from under Vault since then.
+	if err := verifyChecksum(pluginRunner.Command, pluginRunner.Sha256); err != nil {
+		return err
+	}
 
-		id, err := base62.Random(10)
-		if err != nil {
-			return nil, err
+	dataSource, err := dataSourceFromRunner(pluginRunner)
+	if err != nil {
+		return fmt.Errorf("failed to resolve plugin data source: %w", err)
+	}
+	data, err := dataSource.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch plugin data: %w", err)
+	}
+
+	client, err := pluginRunner.RunConfig(ctx,
+		pluginutil.Runner(sys),
+		pluginutil.PluginSets(v5.PluginSets),
+		pluginutil.HandshakeConfig(v5.HandshakeConfig),
+		pluginutil.Logger(namedLogger),
+		pluginutil.MetadataMode(isMetadataMode),
+		pluginutil.AutoMTLS(true),
+		pluginutil.PluginData(data),
+	)
+	if err != nil {
+		return err
+	}
+
+	mpc.client = client
+	mpc.dataHash = hashPluginData(data)
+	// Get the protocol client for this connection.
+	// Subsequent calls to this will return the same client.
+	rpcClient, err := mpc.client.Client()
+	if err != nil {
+		mpc.client.Kill()
+		mpc.resetClient()
+		return err
+	}
+
+	// set the ClientProtocol connection for the given ID
+	mpc.protocol = rpcClient
+
+	gc, ok := rpcClient.(*plugin.GRPCClient)
+	if ok {
+		mpc.clientConn = gc.Conn
+	}
+
+	// min_version/max_version are checked against the plugin's recorded
+	// Version rather than anything negotiated live over the wire: this
+	// series never adds a component to v5.PluginSets for plugins to report
+	// their own version through, so the only version Vault can actually
+	// vouch for is the one recorded in the catalog at registration time
+	// (explicitly via config, or from the registry manifest for a Pull'd
+	// plugin).
+	if pluginRunner.MinVersion != "" || pluginRunner.MaxVersion != "" {
+		if err := checkVersionConstraint(pluginRunner.Version, pluginRunner.MinVersion, pluginRunner.MaxVersion); err != nil {
+			mpc.client.Kill()
+			mpc.resetClient()
+			return err
 		}
+	}
 
-		mpc.id = id
-		mpc.name = pluginRunner.Name
+	id, err := base62.Random(10)
+	if err != nil {
+		return err
 	}
-	mpc.connectionCount += 1
 
-	return mpc, nil
+	mpc.id = id
+	mpc.name = pluginRunner.Name
+	mpc.spawnArgs = &spawnArgs{
+		sys:            sys,
+		pluginRunner:   pluginRunner,
+		namedLogger:    namedLogger,
+		isMetadataMode: isMetadataMode,
+	}
+
+	return nil
 }
 
 // getPluginTypeFromUnknown will attempt to run the plugin to determine the
@@ -329,6 +500,10 @@ func (c *PluginCatalog) UpgradePlugins(ctx context.Context, logger log.Logger) e
 		// prepend the plugin directory to the command
 		cmdOld := plugin.Command
 		plugin.Command = filepath.Join(c.directory, plugin.Command)
+		// This entry pre-dates the Registered/Enabled/Disabled lifecycle and
+		// has no State of its own; treat it as enabled so it can be probed
+		// below via getPluginClient.
+		plugin.State = string(PluginStateEnabled)
 
 		pluginType, err := c.getPluginTypeFromUnknown(ctx, logger, plugin)
 		if err != nil {
@@ -341,12 +516,28 @@ func (c *PluginCatalog) UpgradePlugins(ctx context.Context, logger log.Logger) e
 		}
 
 		// Upgrade the storage
-		err = c.setInternal(ctx, pluginName, pluginType, cmdOld, plugin.Args, plugin.Env, plugin.Sha256)
+		err = c.setInternal(ctx, &PluginConfig{
+			Type:     pluginType,
+			Name:     pluginName,
+			Path:     cmdOld,
+			Checksum: plugin.Sha256,
+			Args:     plugin.Args,
+			Env:      plugin.Env,
+		}, "")
 		if err != nil {
 			retErr = multierror.Append(retErr, fmt.Errorf("could not upgrade plugin %s: %s", pluginName, err))
 			continue
 		}
 
+		// Plugins upgraded from the untyped storage format were already in
+		// use; preserve that instead of leaving them in the Registered
+		// state new registrations start in, which would otherwise silently
+		// stop every one of them on next use.
+		if err := c.setState(ctx, pluginName, pluginType, PluginStateEnabled); err != nil {
+			retErr = multierror.Append(retErr, fmt.Errorf("could not enable upgraded plugin %s: %s", pluginName, err))
+			continue
+		}
+
 		err = c.catalogView.Delete(ctx, pluginName)
 		if err != nil {
 			logger.Error("could not remove plugin", "plugin", pluginName, "error", err)
@@ -355,6 +546,60 @@ func (c *PluginCatalog) UpgradePlugins(ctx context.Context, logger log.Logger) e
 		logger.Info("upgraded plugin type", "plugin", pluginName, "type", pluginType.String())
 	}
 
+	// Entries already stored under the typed key format can still predate
+	// the Registered/Enabled/Disabled lifecycle, since that was added after
+	// plugin types were; they're not reached by the untyped-key migration
+	// above. Without this, every external plugin already in use before this
+	// lifecycle existed would go dark under getPluginClient's new State
+	// gate the next time Vault starts, until an operator manually
+	// re-enabled each one.
+	if err := c.migrateTypedPluginStates(ctx, logger); err != nil {
+		retErr = multierror.Append(retErr, err)
+	}
+
+	return retErr
+}
+
+// migrateTypedPluginStates flips the State of every already-typed catalog
+// entry whose State is unset from empty to Enabled, so that getPluginClient
+// keeps spawning it. The caller must hold c.lock.
+func (c *PluginCatalog) migrateTypedPluginStates(ctx context.Context, logger log.Logger) error {
+	keys, err := logical.CollectKeys(ctx, c.catalogView)
+	if err != nil {
+		return fmt.Errorf("failed to list plugin catalog entries: %w", err)
+	}
+
+	var retErr error
+	for _, key := range keys {
+		if !strings.Contains(key, "/") {
+			// Handled by the untyped-key migration above.
+			continue
+		}
+
+		out, err := c.catalogView.Get(ctx, key)
+		if err != nil || out == nil {
+			continue
+		}
+
+		entry := new(pluginutil.PluginRunner)
+		if err := jsonutil.DecodeJSON(out.Value, entry); err != nil {
+			retErr = multierror.Append(retErr, fmt.Errorf("failed to decode plugin entry %q: %w", key, err))
+			continue
+		}
+
+		if entry.State != "" {
+			continue
+		}
+
+		entry.State = string(PluginStateEnabled)
+		if err := c.persist(ctx, entry); err != nil {
+			retErr = multierror.Append(retErr, fmt.Errorf("could not migrate state for plugin %q: %w", key, err))
+			continue
+		}
+
+		logger.Info("migrated pre-lifecycle plugin to enabled state", "plugin", entry.Name)
+	}
+
 	return retErr
 }
 
@@ -412,30 +657,106 @@ func (c *PluginCatalog) get(ctx context.Context, name string, pluginType consts.
 	return nil, nil
 }
 
-// Set registers a new external plugin with the catalog, or updates an existing
-// external plugin. It takes the name, command and SHA256 of the plugin.
+// getRaw returns the stored entry for name/pluginType exactly as persisted,
+// without resolving Command to an absolute path the way get does. Callers
+// that only read the entry should use get (or Get); callers that
+// read-modify-write it, like setState, must use getRaw instead, so the
+// relative Command they read is the same one they persist back — resolving
+// it first and persisting the resolved copy corrupts the entry, since the
+// next get would join the plugin directory onto an already-absolute path.
+func (c *PluginCatalog) getRaw(ctx context.Context, name string, pluginType consts.PluginType) (*pluginutil.PluginRunner, error) {
+	if c.directory == "" {
+		return nil, nil
+	}
+
+	out, err := c.catalogView.Get(ctx, pluginType.String()+"/"+name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve plugin %q: %w", name, err)
+	}
+	if out == nil {
+		out, err = c.catalogView.Get(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve plugin %q: %w", name, err)
+		}
+	}
+	if out == nil {
+		return nil, nil
+	}
+
+	entry := new(pluginutil.PluginRunner)
+	if err := jsonutil.DecodeJSON(out.Value, entry); err != nil {
+		return nil, fmt.Errorf("failed to decode plugin entry: %w", err)
+	}
+	if entry.Type != pluginType && entry.Type != consts.PluginTypeUnknown {
+		return nil, nil
+	}
+
+	return entry, nil
+}
+
+// Set registers a new external plugin with the catalog, or updates an
+// existing external plugin.
 func (c *PluginCatalog) Set(ctx context.Context, name string, pluginType consts.PluginType, command string, args []string, env []string, sha256 []byte) error {
+	return c.SetConfig(ctx, &PluginConfig{
+		Type:     pluginType,
+		Name:     name,
+		Path:     command,
+		Args:     args,
+		Env:      env,
+		Checksum: sha256,
+	})
+}
+
+// SetConfig registers a new external plugin with the catalog, or updates an
+// existing external plugin, from a config block parsed by ParsePluginConfig
+// (or assembled directly). Unlike Set, it exposes the declarative extras
+// ParsePluginConfig can produce: a Data source and min/max version
+// constraints. A new plugin is registered in the Registered state; call
+// Enable before it can be used by getPluginClient. Re-registering an
+// existing plugin keeps its current state.
+func (c *PluginCatalog) SetConfig(ctx context.Context, config *PluginConfig) error {
 	if c.directory == "" {
 		return ErrDirectoryNotConfigured
 	}
 
+	if err := validatePluginPathComponents(config.Name, config.Path); err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.setInternal(ctx, config, "")
+}
+
+// validatePluginPathComponents rejects a plugin name or command containing
+// ".." path segments, so that neither can be used to break out of the
+// configured plugin directory once joined onto it. Every path into the
+// catalog that's built from externally-supplied strings, whether from Set
+// or from a registry manifest via Pull, must go through this before the
+// string touches a filepath.Join.
+func validatePluginPathComponents(name, command string) error {
 	switch {
 	case strings.Contains(name, ".."):
 		fallthrough
 	case strings.Contains(command, ".."):
 		return consts.ErrPathContainsParentReferences
 	}
-
-	c.lock.Lock()
-	defer c.lock.Unlock()
-
-	return c.setInternal(ctx, name, pluginType, command, args, env, sha256)
+	return nil
 }
 
-func (c *PluginCatalog) setInternal(ctx context.Context, name string, pluginType consts.PluginType, command string, args []string, env []string, sha256 []byte) error {
+// setInternal persists a plugin entry to the catalog. reference, if
+// non-empty, is the registry reference the plugin was pulled from and is
+// stored on the entry's Reference field; an empty reference clears it.
+func (c *PluginCatalog) setInternal(ctx context.Context, config *PluginConfig, reference string) error {
 	// Best effort check to make sure the command isn't breaking out of the
-	// configured plugin directory.
-	commandFull := filepath.Join(c.directory, command)
+	// configured plugin directory. A command may resolve either directly
+	// into the plugin directory, or into the content-addressable blob store
+	// rooted under it (for plugins installed via Pull/Upgrade); in the
+	// latter case the CAS path itself, keyed by the binary's digest, is the
+	// source of truth, so resolving there is just as safe as resolving
+	// directly into the plugin directory.
+	commandFull := filepath.Join(c.directory, config.Path)
 	sym, err := filepath.EvalSymlinks(commandFull)
 	if err != nil {
 		return fmt.Errorf("error while validating the command path: %w", err)
@@ -445,21 +766,32 @@ func (c *PluginCatalog) setInternal(ctx context.Context, name string, pluginType
 		return fmt.Errorf("error while validating the command path: %w", err)
 	}
 
-	if symAbs != c.directory {
+	blobsRoot := filepath.Join(c.directory, blobsDir, "sha256")
+	if symAbs != c.directory && symAbs != blobsRoot {
 		return errors.New("cannot execute files outside of configured plugin directory")
 	}
 
+	if err := verifyChecksum(commandFull, config.Checksum); err != nil {
+		return err
+	}
+
+	pluginType := config.Type
+
 	// If the plugin type is unknown, we want to attempt to determine the type
 	if pluginType == consts.PluginTypeUnknown {
 		// entryTmp should only be used for the below type check, it uses the
 		// full command instead of the relative command.
 		entryTmp := &pluginutil.PluginRunner{
-			Name:    name,
+			Name:    config.Name,
 			Command: commandFull,
-			Args:    args,
-			Env:     env,
-			Sha256:  sha256,
+			Args:    config.Args,
+			Env:     config.Env,
+			Sha256:  config.Checksum,
 			Builtin: false,
+			// This is only ever used to probe the plugin's type by actually
+			// running it; it's never persisted, so mark it enabled to get
+			// past getPluginClient's lifecycle gate.
+			State: string(PluginStateEnabled),
 		}
 
 		pluginType, err = c.getPluginTypeFromUnknown(ctx, log.Default(), entryTmp)
@@ -471,23 +803,59 @@ func (c *PluginCatalog) setInternal(ctx context.Context, name string, pluginType
 		}
 	}
 
-	entry := &pluginutil.PluginRunner{
-		Name:    name,
-		Type:    pluginType,
-		Command: command,
-		Args:    args,
-		Env:     env,
-		Sha256:  sha256,
-		Builtin: false,
+	if config.Data == nil {
+		config.Data = FixedData(nil)
 	}
 
+	// Preserve whatever lifecycle state an existing entry of this name/type
+	// already has, instead of clobbering it back to Registered: setInternal
+	// also serves as the update path for Pull/Upgrade, and for Set/SetConfig
+	// re-registering an already-Enabled plugin to rotate its args or env.
+	// Resetting State here would silently stop a working plugin the next
+	// time something calls getPluginClient. Only a genuinely new entry
+	// starts out Registered, pending an operator's explicit Enable.
+	state := PluginStateRegistered
+	if existing, err := c.getRaw(ctx, config.Name, pluginType); err == nil && existing != nil && existing.State != "" {
+		state = PluginState(existing.State)
+	}
+
+	entry := &pluginutil.PluginRunner{
+		Name:           config.Name,
+		Type:           pluginType,
+		Command:        config.Path,
+		Args:           config.Args,
+		Env:            config.Env,
+		Sha256:         config.Checksum,
+		Builtin:        false,
+		Reference:      reference,
+		DataSourceKind: string(config.Data.Kind()),
+		DataSourceRef:  config.Data.Ref(),
+		Version:        config.Version,
+		MinVersion:     config.MinVersion,
+		MaxVersion:     config.MaxVersion,
+		State:          string(state),
+	}
+
+	// FixedData's bytes live in the config block itself rather than behind
+	// a Ref, so they need to be persisted onto the entry directly; a
+	// FileData source is re-read from disk on every fetch instead.
+	if fixed, ok := config.Data.(FixedData); ok {
+		entry.Data = []byte(fixed)
+	}
+
+	return c.persist(ctx, entry)
+}
+
+// persist encodes a plugin entry and writes it to the catalog view under
+// its type/name key. The caller must hold c.lock.
+func (c *PluginCatalog) persist(ctx context.Context, entry *pluginutil.PluginRunner) error {
 	buf, err := json.Marshal(entry)
 	if err != nil {
 		return fmt.Errorf("failed to encode plugin entry: %w", err)
 	}
 
 	logicalEntry := logical.StorageEntry{
-		Key:   pluginType.String() + "/" + name,
+		Key:   entry.Type.String() + "/" + entry.Name,
 		Value: buf,
 	}
 	if err := c.catalogView.Put(ctx, &logicalEntry); err != nil {
@@ -512,9 +880,22 @@ func (c *PluginCatalog) Delete(ctx context.Context, name string, pluginType cons
 	return c.catalogView.Delete(ctx, pluginKey)
 }
 
-// List returns a list of all the known plugin names. If an external and builtin
-// plugin share the same name, only one instance of the name will be returned.
+// List returns a list of all the known plugin names. If an external and
+// builtin plugin share the same name, only one instance of the name will be
+// returned.
 func (c *PluginCatalog) List(ctx context.Context, pluginType consts.PluginType) ([]string, error) {
+	return c.listInternal(ctx, pluginType, false)
+}
+
+// ListVerified behaves like List, but excludes external plugins whose
+// on-disk binary no longer hashes to their stored sha256, so operators can
+// use it to detect tampering across the fleet; builtin plugins have no
+// on-disk binary to check and always count as verified.
+func (c *PluginCatalog) ListVerified(ctx context.Context, pluginType consts.PluginType) ([]string, error) {
+	return c.listInternal(ctx, pluginType, true)
+}
+
+func (c *PluginCatalog) listInternal(ctx context.Context, pluginType consts.PluginType, onlyVerified bool) ([]string, error) {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
 
@@ -534,16 +915,24 @@ func (c *PluginCatalog) List(ctx context.Context, pluginType consts.PluginType)
 
 	for _, plugin := range keys {
 		// Only list user-added plugins if they're of the given type.
-		if entry, err := c.get(ctx, plugin, pluginType); err == nil && entry != nil {
+		entry, err := c.get(ctx, plugin, pluginType)
+		if err != nil || entry == nil {
+			continue
+		}
 
-			// Some keys will be prepended with the plugin type, but other ones won't.
-			// Users don't expect to see the plugin type, so we need to strip that here.
-			idx := strings.Index(plugin, pluginTypePrefix)
-			if idx == 0 {
-				plugin = plugin[len(pluginTypePrefix):]
+		if onlyVerified && !entry.Builtin {
+			if err := verifyChecksum(entry.Command, entry.Sha256); err != nil {
+				continue
 			}
-			mapKeys[plugin] = true
 		}
+
+		// Some keys will be prepended with the plugin type, but other ones won't.
+		// Users don't expect to see the plugin type, so we need to strip that here.
+		idx := strings.Index(plugin, pluginTypePrefix)
+		if idx == 0 {
+			plugin = plugin[len(pluginTypePrefix):]
+		}
+		mapKeys[plugin] = true
 	}
 
 	for _, plugin := range builtinKeys {
@@ -0,0 +1,123 @@
+package vault
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+)
+
+// PluginConfig is the declarative description of a plugin registration, as
+// produced by ParsePluginConfig. SetConfig and setInternal take it in place
+// of a long positional argument list (Set keeps its positional signature and
+// builds one), so that new optional fields (like Data) don't require
+// touching every call site.
+type PluginConfig struct {
+	Type     consts.PluginType
+	Name     string
+	Path     string
+	Checksum []byte
+	Args     []string
+	Env      []string
+
+	// Version is the semver version of this plugin, if known (e.g. from a
+	// registry manifest). It's informational; use MinVersion/MaxVersion to
+	// actually constrain what's allowed to run.
+	Version string
+
+	// MinVersion and MaxVersion, if set, constrain the plugin's recorded
+	// Version, checked each time the plugin process is spawned; the running
+	// plugin binary is not asked for its version. Either may be left empty
+	// to leave that bound unconstrained.
+	MinVersion string
+	MaxVersion string
+
+	// Data supplies the plugin's startup config. It's re-fetched on every
+	// plugin spawn and on Reload, so operators can rotate things like
+	// database credential templates without re-registering the plugin.
+	Data DataSource
+}
+
+// pluginConfigRaw mirrors a plugin config block's on-the-wire shape for
+// HCL/JSON decoding, before Data is resolved into a concrete DataSource.
+type pluginConfigRaw struct {
+	Name       string   `hcl:"name"`
+	Path       string   `hcl:"path"`
+	Checksum   string   `hcl:"checksum"`
+	Args       []string `hcl:"args"`
+	Env        []string `hcl:"env"`
+	Data       string   `hcl:"data"`
+	DataFile   string   `hcl:"data_file"`
+	Version    string   `hcl:"version"`
+	MinVersion string   `hcl:"min_version"`
+	MaxVersion string   `hcl:"max_version"`
+}
+
+// ParsePluginConfig parses a plugin registration config block in either HCL
+// or JSON form. JSON is valid HCL, so this always goes through the HCL
+// parser; operators can use either format interchangeably.
+func ParsePluginConfig(pluginType consts.PluginType, raw []byte) (*PluginConfig, error) {
+	var decoded pluginConfigRaw
+	if err := hcl.Decode(&decoded, string(raw)); err != nil {
+		return nil, fmt.Errorf("failed to parse plugin config: %w", err)
+	}
+
+	if decoded.Name == "" {
+		return nil, fmt.Errorf("plugin config is missing a name")
+	}
+	if decoded.Path == "" {
+		return nil, fmt.Errorf("plugin config is missing a path")
+	}
+
+	var checksum []byte
+	if decoded.Checksum != "" {
+		var err error
+		checksum, err = hex.DecodeString(decoded.Checksum)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checksum in plugin config: %w", err)
+		}
+	}
+
+	if decoded.Data != "" && decoded.DataFile != "" {
+		return nil, fmt.Errorf("plugin config cannot set both data and data_file")
+	}
+
+	var data DataSource
+	switch {
+	case decoded.DataFile != "":
+		data = FileData(decoded.DataFile)
+	default:
+		data = FixedData(decoded.Data)
+	}
+
+	if decoded.Version != "" {
+		if _, err := version.NewVersion(decoded.Version); err != nil {
+			return nil, fmt.Errorf("invalid version in plugin config: %w", err)
+		}
+	}
+	if decoded.MinVersion != "" {
+		if _, err := version.NewVersion(decoded.MinVersion); err != nil {
+			return nil, fmt.Errorf("invalid min_version in plugin config: %w", err)
+		}
+	}
+	if decoded.MaxVersion != "" {
+		if _, err := version.NewVersion(decoded.MaxVersion); err != nil {
+			return nil, fmt.Errorf("invalid max_version in plugin config: %w", err)
+		}
+	}
+
+	return &PluginConfig{
+		Type:       pluginType,
+		Name:       decoded.Name,
+		Path:       decoded.Path,
+		Checksum:   checksum,
+		Args:       decoded.Args,
+		Env:        decoded.Env,
+		Data:       data,
+		Version:    decoded.Version,
+		MinVersion: decoded.MinVersion,
+		MaxVersion: decoded.MaxVersion,
+	}, nil
+}
@@ -0,0 +1,83 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/vault/sdk/helper/pluginutil"
+)
+
+// DataSourceKind identifies which DataSource implementation backs a plugin
+// catalog entry's config data, so the catalog can re-hydrate the right type
+// from storage.
+type DataSourceKind string
+
+const (
+	// DataSourceFixed holds its config inline in the catalog entry.
+	DataSourceFixed DataSourceKind = "fixed"
+	// DataSourceFile re-reads its config from an external file on every
+	// fetch, so edits to that file take effect without re-registering the
+	// plugin.
+	DataSourceFile DataSourceKind = "file"
+)
+
+// DataSource supplies the config data a plugin is started with. Unlike a
+// plugin's Args or Env, which are fixed at registration time, a DataSource
+// is re-evaluated every time a plugin process is spawned, which lets
+// operators rotate things like database credential templates by editing a
+// file rather than re-registering the plugin.
+type DataSource interface {
+	// Kind identifies the DataSource implementation for storage.
+	Kind() DataSourceKind
+	// Ref is the stored reference for this DataSource (empty for Fixed,
+	// the file path for File).
+	Ref() string
+	// Get returns the current config data.
+	Get(ctx context.Context) ([]byte, error)
+}
+
+// FixedData is a DataSource whose config is supplied inline and never
+// changes.
+type FixedData []byte
+
+func (f FixedData) Kind() DataSourceKind { return DataSourceFixed }
+
+func (f FixedData) Ref() string { return "" }
+
+func (f FixedData) Get(ctx context.Context) ([]byte, error) {
+	return []byte(f), nil
+}
+
+// FileData is a DataSource that re-reads its config from path on every Get,
+// so external edits to the file are picked up the next time the plugin is
+// started or reloaded.
+type FileData string
+
+func (f FileData) Kind() DataSourceKind { return DataSourceFile }
+
+func (f FileData) Ref() string { return string(f) }
+
+func (f FileData) Get(ctx context.Context) ([]byte, error) {
+	data, err := os.ReadFile(string(f))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin config file %q: %w", string(f), err)
+	}
+	return data, nil
+}
+
+// dataSourceFromRunner reconstructs the DataSource described by a stored
+// PluginRunner entry's DataSourceKind/DataSourceRef/Data fields.
+func dataSourceFromRunner(runner *pluginutil.PluginRunner) (DataSource, error) {
+	switch DataSourceKind(runner.DataSourceKind) {
+	case "", DataSourceFixed:
+		return FixedData(runner.Data), nil
+	case DataSourceFile:
+		if runner.DataSourceRef == "" {
+			return nil, fmt.Errorf("file data source is missing its file path")
+		}
+		return FileData(runner.DataSourceRef), nil
+	default:
+		return nil, fmt.Errorf("unknown plugin data source kind %q", runner.DataSourceKind)
+	}
+}
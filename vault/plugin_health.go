@@ -0,0 +1,291 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// healthCheckInterval is how often the supervisor pings every live
+	// multiplexed client.
+	healthCheckInterval = 15 * time.Second
+	// maxConsecutiveFailures is how many consecutive failed pings a client
+	// may accumulate before the supervisor tears it down and respawns the
+	// plugin process.
+	maxConsecutiveFailures = 3
+	// restartBackoffBase and restartBackoffMax bound the exponential
+	// backoff applied between respawn attempts for a given plugin.
+	restartBackoffBase = 1 * time.Second
+	restartBackoffMax  = 30 * time.Second
+)
+
+// PluginHealth is a point-in-time snapshot of a single plugin's health as
+// tracked by the supervisor.
+type PluginHealth struct {
+	LastPing            time.Time
+	ConsecutiveFailures int
+	RestartCount        int
+	Pid                 int
+}
+
+// DispensedHandle is a stable reference to a dispensed plugin instance.
+// Because the health supervisor may tear down and respawn the underlying
+// plugin process out from under a caller, callers should call Get on every
+// use rather than holding onto the interface{} it once returned.
+type DispensedHandle struct {
+	mu         sync.RWMutex
+	pluginName string
+	component  string
+	current    interface{}
+}
+
+// Get returns the most recently dispensed instance for this handle, which
+// may have been swapped out by the supervisor after a restart.
+func (h *DispensedHandle) Get() interface{} {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.current
+}
+
+func (h *DispensedHandle) set(v interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.current = v
+}
+
+// Dispense wraps MultiplexedClient.Dispense with a registry entry so the
+// health supervisor can re-dispense component on this plugin's behalf after
+// a restart, keeping the handle's Get() pointed at a live instance.
+func (c *PluginCatalog) Dispense(pluginName, component string) (*DispensedHandle, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	mpc, ok := c.multiplexedClients[pluginName]
+	if !ok || mpc.client == nil || mpc.protocol == nil {
+		return nil, fmt.Errorf("no running plugin process for %q", pluginName)
+	}
+
+	instance, err := mpc.Dispense(component)
+	if err != nil {
+		return nil, err
+	}
+
+	handle := &DispensedHandle{pluginName: pluginName, component: component, current: instance}
+
+	if c.dispensedHandles == nil {
+		c.dispensedHandles = make(map[string][]*DispensedHandle)
+	}
+	c.dispensedHandles[pluginName] = append(c.dispensedHandles[pluginName], handle)
+
+	return handle, nil
+}
+
+// startHealthSupervisor launches the background loop that pings every live
+// multiplexed client and respawns plugins that stop responding. It returns
+// immediately; the loop runs until Stop is called. The caller must not
+// already hold c.lock.
+func (c *PluginCatalog) startHealthSupervisor() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c.lock.Lock()
+	c.healthSupervisorCancel = cancel
+	c.lock.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(healthCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.runHealthChecks(ctx)
+			}
+		}
+	}()
+}
+
+// Stop cancels the health supervisor's background loop, if one is running.
+// It's safe to call on a catalog whose supervisor was never started, or
+// more than once. setupPluginCatalog calls this on the outgoing
+// PluginCatalog before installing a new one, so the supervisor goroutine
+// doesn't leak across repeated unseals.
+func (c *PluginCatalog) Stop() {
+	c.lock.Lock()
+	cancel := c.healthSupervisorCancel
+	c.healthSupervisorCancel = nil
+	c.lock.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (c *PluginCatalog) runHealthChecks(ctx context.Context) {
+	c.lock.Lock()
+	names := make([]string, 0, len(c.multiplexedClients))
+	for name, mpc := range c.multiplexedClients {
+		if mpc.client != nil {
+			names = append(names, name)
+		}
+	}
+	c.lock.Unlock()
+
+	for _, name := range names {
+		c.checkAndRecover(ctx, name)
+	}
+}
+
+func (c *PluginCatalog) checkAndRecover(ctx context.Context, name string) {
+	// Snapshot the live protocol handle under the lock, then Ping it
+	// unlocked. Ping has no timeout and blocks until the RPC returns or the
+	// transport errors; holding c.lock for that duration would freeze every
+	// other catalog operation (GetPluginClient, Enable, Disable, List,
+	// Inspect, Reload) behind exactly the unresponsive plugin this
+	// supervisor exists to catch. The snapshotted protocol handle is safe
+	// to call even if a concurrent Close or restart clears mpc.protocol out
+	// from under it: gRPC's ClientConn is safe for concurrent use, and a
+	// Ping against an already-closed connection just returns an error
+	// instead of racing. The liveness check below, after re-acquiring the
+	// lock, catches the case where the result no longer applies to the
+	// client that's current by then.
+	c.lock.Lock()
+	mpc, ok := c.multiplexedClients[name]
+	if !ok || mpc.client == nil || mpc.protocol == nil {
+		c.lock.Unlock()
+		return
+	}
+	protocol := mpc.protocol
+	c.lock.Unlock()
+
+	err := protocol.Ping()
+
+	c.lock.Lock()
+	current, ok := c.multiplexedClients[name]
+	if !ok || current.protocol != protocol {
+		// The client was replaced, closed, or removed while the Ping above
+		// was in flight; this result is stale and shouldn't drive a state
+		// transition for whatever is current now.
+		c.lock.Unlock()
+		return
+	}
+
+	if c.healthStatus == nil {
+		c.healthStatus = make(map[string]*PluginHealth)
+	}
+	health, ok := c.healthStatus[name]
+	if !ok {
+		health = &PluginHealth{}
+		c.healthStatus[name] = health
+	}
+
+	if err == nil {
+		health.LastPing = time.Now()
+		health.ConsecutiveFailures = 0
+		c.lock.Unlock()
+		return
+	}
+
+	health.ConsecutiveFailures++
+	c.logger.Warn("plugin health check failed", "plugin", name, "consecutive_failures", health.ConsecutiveFailures, "error", err)
+
+	if health.ConsecutiveFailures < maxConsecutiveFailures {
+		c.lock.Unlock()
+		return
+	}
+
+	restartCount := health.RestartCount
+	c.logger.Error("plugin unresponsive, restarting", "plugin", name, "restart_count", restartCount+1)
+	c.lock.Unlock()
+
+	// Back off without holding the lock so other catalog operations aren't
+	// blocked for the duration of the wait.
+	backoff := restartBackoffBase << restartCount
+	if backoff > restartBackoffMax || backoff <= 0 {
+		backoff = restartBackoffMax
+	}
+	time.Sleep(backoff)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// The client may have been replaced (or removed, e.g. via Disable)
+	// while we were sleeping; re-fetch it and re-check liveness before
+	// acting, same as above.
+	if current, ok = c.multiplexedClients[name]; !ok || current.protocol != protocol {
+		return
+	}
+
+	if err := c.restartPlugin(ctx, name); err != nil {
+		c.logger.Error("failed to respawn plugin", "plugin", name, "error", err)
+		return
+	}
+
+	health.ConsecutiveFailures = 0
+	health.RestartCount++
+	health.LastPing = time.Now()
+}
+
+// restartPlugin kills name's current plugin process, if any, and spawns a
+// replacement using the parameters recorded at its last spawn, then
+// re-dispenses every outstanding DispensedHandle for it against the new
+// process. It's shared by checkAndRecover, after a failed health check, and
+// Reload, after a FileData source changes, so both restart paths leave
+// dispensed callers pointed at a live instance instead of a dead one. The
+// caller must hold c.lock.
+func (c *PluginCatalog) restartPlugin(ctx context.Context, name string) error {
+	mpc, ok := c.multiplexedClients[name]
+	if !ok {
+		return fmt.Errorf("no running plugin process for %q", name)
+	}
+
+	spawnArgs := mpc.spawnArgs
+	if spawnArgs == nil {
+		return fmt.Errorf("cannot restart plugin %q: no spawn arguments recorded", name)
+	}
+
+	if mpc.client != nil {
+		mpc.client.Kill()
+	}
+	connectionCount := mpc.connectionCount
+	newMPC := c.newMultiplexedClient(name)
+	newMPC.connectionCount = connectionCount
+
+	if err := c.spawnPluginProcess(ctx, newMPC, spawnArgs.sys, spawnArgs.pluginRunner, spawnArgs.namedLogger, spawnArgs.isMetadataMode); err != nil {
+		return err
+	}
+
+	for _, handle := range c.dispensedHandles[name] {
+		instance, err := newMPC.Dispense(handle.component)
+		if err != nil {
+			c.logger.Error("failed to re-dispense plugin instance after restart", "plugin", name, "component", handle.component, "error", err)
+			continue
+		}
+		handle.set(instance)
+	}
+
+	return nil
+}
+
+// HealthStatus returns a snapshot of per-plugin health as tracked by the
+// supervisor: last successful ping, current consecutive failure count, and
+// how many times the plugin has been restarted.
+func (c *PluginCatalog) HealthStatus() map[string]PluginHealth {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	out := make(map[string]PluginHealth, len(c.healthStatus))
+	for name, health := range c.healthStatus {
+		snapshot := *health
+		if mpc, ok := c.multiplexedClients[name]; ok && mpc.client != nil {
+			if reattach := mpc.client.ReattachConfig(); reattach != nil {
+				snapshot.Pid = reattach.Pid
+			}
+		}
+		out[name] = snapshot
+	}
+	return out
+}
@@ -0,0 +1,180 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	plugin "github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/vault/sdk/helper/pluginutil"
+)
+
+// fakeProtocol is a minimal plugin.ClientProtocol that lets these tests drive
+// checkAndRecover's Ping/restart logic without a real plugin subprocess.
+type fakeProtocol struct {
+	mu        sync.Mutex
+	pingErr   error
+	pingCalls int
+}
+
+func (f *fakeProtocol) Ping() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pingCalls++
+	return f.pingErr
+}
+
+func (f *fakeProtocol) Close() error {
+	return nil
+}
+
+func (f *fakeProtocol) Dispense(name string) (interface{}, error) {
+	return "dispensed:" + name, nil
+}
+
+func (f *fakeProtocol) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pingCalls
+}
+
+type fakeRunnerUtil struct{}
+
+func (fakeRunnerUtil) MlockEnabled() bool { return false }
+
+// newTestCatalogWithClient builds a PluginCatalog with a single live
+// MultiplexedClient registered under name, wired to protocol. Builtin: true
+// on the recorded spawnArgs makes a respawn attempt (via restartPlugin) fail
+// fast and deterministically, since RunConfig refuses to exec a builtin
+// plugin as a subprocess — there's no real plugin binary in this tree for a
+// respawn to actually succeed against.
+func newTestCatalogWithClient(t *testing.T, name string, protocol plugin.ClientProtocol) (*PluginCatalog, *MultiplexedClient) {
+	t.Helper()
+
+	c := &PluginCatalog{
+		logger:             log.NewNullLogger(),
+		multiplexedClients: make(map[string]*MultiplexedClient),
+		dispensedHandles:   make(map[string][]*DispensedHandle),
+	}
+
+	mpc := c.newMultiplexedClient(name)
+	mpc.client = &plugin.Client{}
+	mpc.protocol = protocol
+	mpc.connectionCount = 1
+	mpc.spawnArgs = &spawnArgs{
+		sys:            fakeRunnerUtil{},
+		pluginRunner:   &pluginutil.PluginRunner{Name: name, Builtin: true},
+		namedLogger:    log.NewNullLogger(),
+		isMetadataMode: false,
+	}
+
+	return c, mpc
+}
+
+func TestCheckAndRecover_FailuresEscalateAndTriggerRestart(t *testing.T) {
+	proto := &fakeProtocol{pingErr: errors.New("connection refused")}
+	c, mpc := newTestCatalogWithClient(t, "test-plugin", proto)
+	ctx := context.Background()
+
+	for i := 0; i < maxConsecutiveFailures-1; i++ {
+		c.checkAndRecover(ctx, "test-plugin")
+	}
+
+	c.lock.RLock()
+	failures := c.healthStatus["test-plugin"].ConsecutiveFailures
+	unchanged := c.multiplexedClients["test-plugin"] == mpc
+	c.lock.RUnlock()
+
+	if failures != maxConsecutiveFailures-1 {
+		t.Fatalf("expected %d consecutive failures, got %d", maxConsecutiveFailures-1, failures)
+	}
+	if !unchanged {
+		t.Fatal("client should not have been replaced before maxConsecutiveFailures was reached")
+	}
+
+	// The Nth failure crosses the threshold: checkAndRecover should tear
+	// down the old client and hand off to restartPlugin. The respawn itself
+	// fails deterministically (see newTestCatalogWithClient), but the old
+	// MultiplexedClient should still have been replaced, proving
+	// restartPlugin fired rather than spinning forever against the dead
+	// connection.
+	c.checkAndRecover(ctx, "test-plugin")
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	replaced := c.multiplexedClients["test-plugin"]
+	if replaced == mpc {
+		t.Fatal("expected restartPlugin to replace the dead MultiplexedClient")
+	}
+	if replaced.client != nil {
+		t.Fatal("expected the failed respawn to leave no client behind")
+	}
+	if got := proto.calls(); got != maxConsecutiveFailures {
+		t.Fatalf("expected %d pings against the old client, got %d", maxConsecutiveFailures, got)
+	}
+}
+
+func TestPluginCatalog_Dispense_RegistersHandleForRestart(t *testing.T) {
+	proto := &fakeProtocol{}
+	c, _ := newTestCatalogWithClient(t, "test-plugin", proto)
+
+	handle, err := c.Dispense("test-plugin", "database")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := handle.Get(); got != "dispensed:database" {
+		t.Fatalf("unexpected dispensed instance: %v", got)
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	handles := c.dispensedHandles["test-plugin"]
+	if len(handles) != 1 || handles[0] != handle {
+		t.Fatal("expected Dispense to register the handle so restartPlugin can re-dispense it later")
+	}
+}
+
+// TestCheckAndRecover_ConcurrentClose drives checkAndRecover and
+// MultiplexedClient.Close against the same client concurrently, the way the
+// supervisor goroutine and a caller releasing its connection would overlap
+// in practice. Run with -race: before Close synchronized its field writes
+// against the catalog lock, this raced on mpc.protocol/mpc.client.
+func TestCheckAndRecover_ConcurrentClose(t *testing.T) {
+	proto := &fakeProtocol{}
+	c, mpc := newTestCatalogWithClient(t, "test-plugin", proto)
+	ctx := context.Background()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				c.checkAndRecover(ctx, "test-plugin")
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if err := mpc.Close(); err != nil {
+				t.Errorf("unexpected error from Close: %v", err)
+			}
+		}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+}
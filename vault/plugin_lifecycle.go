@@ -0,0 +1,157 @@
+package vault
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/helper/consts"
+)
+
+// PluginState describes where a plugin catalog entry sits in its lifecycle,
+// independent of whether it's merely present in the catalog. This replaces
+// the old binary "in catalog / not in catalog" model, under which the only
+// way to stop a plugin from being used was Delete, which could orphan any
+// process already running against it.
+type PluginState string
+
+const (
+	// PluginStateRegistered means the plugin has been added to the catalog
+	// but has not yet been explicitly enabled.
+	PluginStateRegistered PluginState = "registered"
+	// PluginStateEnabled means the plugin may be spawned and used normally.
+	PluginStateEnabled PluginState = "enabled"
+	// PluginStateDisabled means getPluginClient will refuse to spawn the
+	// plugin, and any running process has been (or is being) torn down.
+	PluginStateDisabled PluginState = "disabled"
+)
+
+// ErrPluginDisabled is returned by getPluginClient when a plugin has been
+// disabled via Disable.
+var ErrPluginDisabled = errors.New("plugin is disabled")
+
+// ErrPluginNotEnabled is returned by getPluginClient when a plugin has been
+// registered but not yet explicitly enabled via Enable.
+var ErrPluginNotEnabled = errors.New("plugin is registered but not enabled")
+
+// PluginInspection reports a plugin's current lifecycle state and, if it
+// has a live process, that process's identity, so operators can check
+// whether it's safe to disable or remove a plugin before doing so.
+type PluginInspection struct {
+	Name            string
+	Type            consts.PluginType
+	State           PluginState
+	Sha256          string
+	ID              string
+	Pid             int
+	ConnectionCount int
+}
+
+// Enable marks a previously registered or disabled plugin as enabled,
+// allowing getPluginClient to spawn it again.
+func (c *PluginCatalog) Enable(ctx context.Context, name string, pluginType consts.PluginType) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.setState(ctx, name, pluginType, PluginStateEnabled)
+}
+
+// Disable marks a plugin as disabled, refusing new plugin processes for it
+// going forward. If force is false, Disable fails when any MultiplexedClient
+// for this plugin still has active connections, so operators don't
+// accidentally sever database backends mid-use. If force is true, the
+// multiplexed client (and its plugin process) is torn down immediately;
+// any caller still holding a Dispense'd instance will see its underlying
+// connection fail on the next RPC.
+func (c *PluginCatalog) Disable(ctx context.Context, name string, pluginType consts.PluginType, force bool) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	mpc, ok := c.multiplexedClients[name]
+	if ok && mpc.connectionCount > 0 {
+		if !force {
+			return fmt.Errorf("refusing to disable plugin %q: %d active connection(s); use force to override", name, mpc.connectionCount)
+		}
+
+		c.logger.Warn("force-disabling plugin with active connections", "plugin", name, "connections", mpc.connectionCount)
+		if mpc.client != nil {
+			mpc.client.Kill()
+		}
+		delete(c.multiplexedClients, name)
+
+		// Drop any DispensedHandles for this plugin along with its client:
+		// left in place, they'd keep pointing at the now-killed instances
+		// until the health supervisor or a later Reload happened to restart
+		// this plugin for an unrelated reason, and Get() would silently
+		// hand callers a dead instance in the meantime.
+		delete(c.dispensedHandles, name)
+	}
+
+	return c.setState(ctx, name, pluginType, PluginStateDisabled)
+}
+
+// setState loads the stored entry for name/pluginType, updates its State,
+// and persists it. It uses getRaw rather than get so the Command it
+// persists back is the original relative path, not get's directory-resolved
+// absolute one. The caller must hold c.lock.
+func (c *PluginCatalog) setState(ctx context.Context, name string, pluginType consts.PluginType, state PluginState) error {
+	runner, err := c.getRaw(ctx, name, pluginType)
+	if err != nil {
+		return err
+	}
+	if runner == nil {
+		if _, ok := c.builtinRegistry.Get(name, pluginType); ok {
+			return fmt.Errorf("cannot change lifecycle state of builtin plugin %q", name)
+		}
+		return ErrPluginNotFound
+	}
+
+	runner.State = string(state)
+
+	return c.persist(ctx, runner)
+}
+
+// Inspect returns the current lifecycle state of a plugin, along with the
+// identity of its running process (if any), so operators can check
+// connection counts and PIDs before disabling or deleting it.
+func (c *PluginCatalog) Inspect(ctx context.Context, name string, pluginType consts.PluginType) (*PluginInspection, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	runner, err := c.get(ctx, name, pluginType)
+	if err != nil {
+		return nil, err
+	}
+	if runner == nil {
+		return nil, ErrPluginNotFound
+	}
+
+	// An empty State means getPluginClient will refuse to spawn this
+	// plugin (its lifecycle gate treats anything but an explicit "enabled"
+	// as not enabled); report it the same way here instead of claiming
+	// "enabled" for a plugin that getPluginClient would actually reject.
+	state := PluginState(runner.State)
+	if state == "" {
+		state = PluginStateRegistered
+	}
+
+	inspection := &PluginInspection{
+		Name:   name,
+		Type:   pluginType,
+		State:  state,
+		Sha256: hex.EncodeToString(runner.Sha256),
+	}
+
+	if mpc, ok := c.multiplexedClients[name]; ok {
+		inspection.ID = mpc.id
+		inspection.ConnectionCount = mpc.connectionCount
+		if mpc.client != nil {
+			if reattach := mpc.client.ReattachConfig(); reattach != nil {
+				inspection.Pid = reattach.Pid
+			}
+		}
+	}
+
+	return inspection, nil
+}
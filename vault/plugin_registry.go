@@ -0,0 +1,393 @@
+package vault
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/helper/consts"
+)
+
+// blobsDir is the name of the content-addressable blob store rooted under the
+// plugin directory. Blobs are laid out as blobs/sha256/<digest>, mirroring
+// the OCI image-spec CAS layout so that the path itself is sufficient proof
+// of content integrity.
+const blobsDir = "blobs"
+
+// RegistryAuthConfig carries the credentials used to authenticate against a
+// plugin registry for Pull and Push operations. Exactly one of the two
+// credential styles should be set.
+type RegistryAuthConfig struct {
+	Username string
+	Password string
+	Token    string
+}
+
+// PluginReference identifies a plugin image in a registry, e.g.
+// "registry.example.com/vault-plugins/mysql:v1.2.3".
+type PluginReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+}
+
+// ParsePluginReference parses a registry reference of the form
+// host[:port]/repository[:tag]. If no tag is supplied, "latest" is assumed.
+func ParsePluginReference(ref string) (*PluginReference, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("plugin reference cannot be empty")
+	}
+
+	slash := strings.Index(ref, "/")
+	if slash < 0 {
+		return nil, fmt.Errorf("plugin reference %q must include a registry host", ref)
+	}
+
+	registry := ref[:slash]
+	rest := ref[slash+1:]
+
+	tag := "latest"
+	if colon := strings.LastIndex(rest, ":"); colon >= 0 {
+		tag = rest[colon+1:]
+		rest = rest[:colon]
+	}
+
+	if rest == "" {
+		return nil, fmt.Errorf("plugin reference %q must include a repository", ref)
+	}
+
+	return &PluginReference{
+		Registry:   registry,
+		Repository: rest,
+		Tag:        tag,
+	}, nil
+}
+
+func (r *PluginReference) String() string {
+	return fmt.Sprintf("%s/%s:%s", r.Registry, r.Repository, r.Tag)
+}
+
+// PluginManifest describes a plugin image as resolved from a registry. It is
+// intentionally narrow compared to a full OCI manifest: Vault only needs the
+// single binary blob plus the metadata an operator needs to approve the
+// install.
+type PluginManifest struct {
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	Sha256       string            `json:"sha256"`
+	Size         int64             `json:"size"`
+	Capabilities []string          `json:"capabilities,omitempty"`
+	Env          []string          `json:"env,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// PluginPrivileges is the subset of a PluginManifest an operator must review
+// and confirm before a plugin is installed from a registry.
+type PluginPrivileges struct {
+	Name         string   `json:"name"`
+	Version      string   `json:"version"`
+	Capabilities []string `json:"capabilities,omitempty"`
+	Env          []string `json:"env,omitempty"`
+}
+
+// registryClient is the transport used to resolve manifests and fetch/push
+// blobs. It is a narrow interface so that tests can swap in a fake without
+// standing up a real registry.
+type registryClient interface {
+	ResolveManifest(ctx context.Context, ref *PluginReference, auth *RegistryAuthConfig) (*PluginManifest, error)
+	FetchBlob(ctx context.Context, ref *PluginReference, sha256Hex string) (io.ReadCloser, error)
+	PushManifest(ctx context.Context, ref *PluginReference, auth *RegistryAuthConfig, manifest *PluginManifest) error
+	PushBlob(ctx context.Context, ref *PluginReference, auth *RegistryAuthConfig, sha256Hex string, size int64, r io.Reader) error
+}
+
+// blobStore is an immutable content-addressable store rooted under the
+// plugin directory. Entries are named by their sha256 digest, and the
+// plugin directory holds symlinks that point at blobStore entries, which
+// lets multiple versions of a plugin binary coexist on disk.
+type blobStore struct {
+	root string
+}
+
+func newBlobStore(pluginDirectory string) *blobStore {
+	return &blobStore{root: filepath.Join(pluginDirectory, blobsDir, "sha256")}
+}
+
+// sha256HexPattern matches a hex-encoded sha256 digest: exactly 64 lowercase
+// hex characters, nothing else. Manifest digests are used to build paths
+// into the blob store, so they're validated against this before they ever
+// reach a filepath.Join.
+var sha256HexPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+func isValidSha256Hex(s string) bool {
+	return sha256HexPattern.MatchString(s)
+}
+
+func (b *blobStore) path(sha256Hex string) string {
+	return filepath.Join(b.root, sha256Hex)
+}
+
+func (b *blobStore) has(sha256Hex string) bool {
+	_, err := os.Stat(b.path(sha256Hex))
+	return err == nil
+}
+
+// write streams r into the blob store under a temp name, verifying its
+// digest matches sha256Hex before renaming it into place, and returns an
+// error if the content doesn't match.
+func (b *blobStore) write(sha256Hex string, r io.Reader) error {
+	if err := os.MkdirAll(b.root, 0o750); err != nil {
+		return fmt.Errorf("failed to create blob store: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(b.root, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp blob: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to download blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to finalize blob: %w", err)
+	}
+
+	digest := hex.EncodeToString(h.Sum(nil))
+	if digest != sha256Hex {
+		return fmt.Errorf("blob digest mismatch: manifest declared %s, downloaded content hashed to %s", sha256Hex, digest)
+	}
+
+	if err := os.Chmod(tmpPath, 0o750); err != nil {
+		return fmt.Errorf("failed to set blob permissions: %w", err)
+	}
+
+	return os.Rename(tmpPath, b.path(sha256Hex))
+}
+
+// Pull resolves ref against the registry, downloads its blob into the
+// content-addressable store (verifying the digest against the manifest as
+// it streams), symlinks it into the plugin directory under name, and
+// registers it in the catalog via setInternal.
+func (c *PluginCatalog) Pull(ctx context.Context, refString string, pluginType consts.PluginType, authConfig *RegistryAuthConfig) error {
+	if c.directory == "" {
+		return ErrDirectoryNotConfigured
+	}
+
+	ref, err := ParsePluginReference(refString)
+	if err != nil {
+		return err
+	}
+
+	client := c.registryClient()
+
+	manifest, err := client.ResolveManifest(ctx, ref, authConfig)
+	if err != nil {
+		return fmt.Errorf("failed to resolve plugin reference %q: %w", ref, err)
+	}
+
+	// manifest.Name and manifest.Sha256 come straight from the registry
+	// response and are about to be used to build filesystem paths (the
+	// plugin-directory symlink and the blob store entry); validate them
+	// with the same rigor as Set before either touches a path, so a
+	// malicious or compromised registry can't use them to write outside
+	// the plugin directory.
+	if err := validatePluginPathComponents(manifest.Name, manifest.Name); err != nil {
+		return fmt.Errorf("invalid plugin name in manifest for %q: %w", ref, err)
+	}
+	if !isValidSha256Hex(manifest.Sha256) {
+		return fmt.Errorf("invalid sha256 digest in manifest for %q: must be 64 lowercase hex characters", ref)
+	}
+
+	store := newBlobStore(c.directory)
+
+	if !store.has(manifest.Sha256) {
+		blob, err := client.FetchBlob(ctx, ref, manifest.Sha256)
+		if err != nil {
+			return fmt.Errorf("failed to fetch plugin blob for %q: %w", ref, err)
+		}
+		defer blob.Close()
+
+		if err := store.write(manifest.Sha256, blob); err != nil {
+			return fmt.Errorf("failed to store plugin blob for %q: %w", ref, err)
+		}
+	}
+
+	c.logger.Info("pulled plugin from registry", "reference", ref.String(), "sha256", manifest.Sha256)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.linkAndRegister(ctx, manifest.Name, pluginType, ref, manifest, store)
+}
+
+// linkAndRegister points a symlink in the plugin directory at the CAS entry
+// for manifest.Sha256 and registers the plugin in the catalog via
+// setInternal, whose EvalSymlinks check accepts a command that resolves into
+// the CAS directory as well as one that resolves into the plugin directory.
+func (c *PluginCatalog) linkAndRegister(ctx context.Context, name string, pluginType consts.PluginType, ref *PluginReference, manifest *PluginManifest, store *blobStore) error {
+	linkPath := filepath.Join(c.directory, name)
+
+	// Remove any existing link so repeated Pulls (and Upgrade) can repoint
+	// it at a new digest.
+	if _, err := os.Lstat(linkPath); err == nil {
+		if err := os.Remove(linkPath); err != nil {
+			return fmt.Errorf("failed to replace existing plugin symlink: %w", err)
+		}
+	}
+
+	if err := os.Symlink(store.path(manifest.Sha256), linkPath); err != nil {
+		return fmt.Errorf("failed to link plugin binary into plugin directory: %w", err)
+	}
+
+	sha256Bytes, err := hex.DecodeString(manifest.Sha256)
+	if err != nil {
+		return fmt.Errorf("invalid sha256 digest in manifest: %w", err)
+	}
+
+	config := &PluginConfig{
+		Type:     pluginType,
+		Name:     name,
+		Path:     name,
+		Checksum: sha256Bytes,
+		Env:      manifest.Env,
+		Version:  manifest.Version,
+	}
+	if err := c.setInternal(ctx, config, ref.String()); err != nil {
+		return fmt.Errorf("failed to register plugin %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// Push uploads the plugin binary at localPath, along with a manifest
+// describing it, to the registry reference ref.
+func (c *PluginCatalog) Push(ctx context.Context, refString string, localPath string, manifest *PluginManifest, authConfig *RegistryAuthConfig) error {
+	ref, err := ParsePluginReference(refString)
+	if err != nil {
+		return err
+	}
+
+	fullPath, err := c.resolvePushSource(localPath)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return fmt.Errorf("failed to open plugin binary %q: %w", localPath, err)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat plugin binary %q: %w", localPath, err)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("failed to hash plugin binary %q: %w", localPath, err)
+	}
+	digest := hex.EncodeToString(h.Sum(nil))
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind plugin binary %q: %w", localPath, err)
+	}
+
+	client := c.registryClient()
+
+	if err := client.PushBlob(ctx, ref, authConfig, digest, fi.Size(), f); err != nil {
+		return fmt.Errorf("failed to push plugin blob to %q: %w", ref, err)
+	}
+
+	manifest.Sha256 = digest
+	manifest.Size = fi.Size()
+
+	if err := client.PushManifest(ctx, ref, authConfig, manifest); err != nil {
+		return fmt.Errorf("failed to push plugin manifest to %q: %w", ref, err)
+	}
+
+	c.logger.Info("pushed plugin to registry", "reference", ref.String(), "sha256", digest)
+
+	return nil
+}
+
+// resolvePushSource resolves localPath against the catalog's configured
+// plugin directory and rejects it if the result escapes that directory.
+// This is looser than setInternal's check on a registered command's path:
+// any file at any depth under the plugin directory is accepted.
+// Push is reachable over the HTTP API (see PluginRegistryPaths), so without
+// this an operator-supplied path could be used to read and exfiltrate any
+// file the Vault process can open, not just plugin binaries.
+func (c *PluginCatalog) resolvePushSource(localPath string) (string, error) {
+	if c.directory == "" {
+		return "", ErrDirectoryNotConfigured
+	}
+
+	full := filepath.Join(c.directory, localPath)
+	sym, err := filepath.EvalSymlinks(full)
+	if err != nil {
+		return "", fmt.Errorf("error while validating the plugin binary path: %w", err)
+	}
+
+	dirAbs, err := filepath.Abs(c.directory)
+	if err != nil {
+		return "", fmt.Errorf("error while validating the plugin binary path: %w", err)
+	}
+
+	if sym != dirAbs && !strings.HasPrefix(sym, dirAbs+string(os.PathSeparator)) {
+		return "", fmt.Errorf("cannot push plugin binary %q: resolves outside the configured plugin directory", localPath)
+	}
+
+	return sym, nil
+}
+
+// Privileges resolves ref and returns the capabilities and environment
+// variables declared in its manifest, without downloading the plugin
+// binary, so an operator can review them before calling Pull.
+func (c *PluginCatalog) Privileges(ctx context.Context, refString string, authConfig *RegistryAuthConfig) (*PluginPrivileges, error) {
+	ref, err := ParsePluginReference(refString)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := c.registryClient().ResolveManifest(ctx, ref, authConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve plugin reference %q: %w", ref, err)
+	}
+
+	return &PluginPrivileges{
+		Name:         manifest.Name,
+		Version:      manifest.Version,
+		Capabilities: manifest.Capabilities,
+		Env:          manifest.Env,
+	}, nil
+}
+
+// Upgrade pulls the image at ref and repoints the named plugin's symlink at
+// the newly downloaded blob, leaving the previous blob in the CAS untouched
+// so other versions that still reference it keep working.
+func (c *PluginCatalog) Upgrade(ctx context.Context, refString string, pluginType consts.PluginType, authConfig *RegistryAuthConfig) error {
+	return c.Pull(ctx, refString, pluginType, authConfig)
+}
+
+// registryClient lazily constructs the default HTTP-based registry client.
+// Tests may override this by setting c.registryClientOverride.
+func (c *PluginCatalog) registryClientFactory() registryClient {
+	if c.registryClientOverride != nil {
+		return c.registryClientOverride
+	}
+	return newHTTPRegistryClient(c.logger)
+}
+
+func (c *PluginCatalog) registryClient() registryClient {
+	return c.registryClientFactory()
+}
@@ -0,0 +1,139 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	log "github.com/hashicorp/go-hclog"
+)
+
+// httpRegistryClient implements registryClient against a Docker/OCI
+// distribution-spec v2 registry: manifests are fetched as JSON documents and
+// blobs are streamed from the standard /v2/<repo>/blobs/<digest> endpoint.
+type httpRegistryClient struct {
+	logger log.Logger
+	client *http.Client
+}
+
+func newHTTPRegistryClient(logger log.Logger) *httpRegistryClient {
+	return &httpRegistryClient{
+		logger: logger,
+		client: http.DefaultClient,
+	}
+}
+
+func (h *httpRegistryClient) manifestURL(ref *PluginReference) string {
+	return fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.Registry, ref.Repository, ref.Tag)
+}
+
+func (h *httpRegistryClient) blobURL(ref *PluginReference, sha256Hex string) string {
+	return fmt.Sprintf("https://%s/v2/%s/blobs/sha256:%s", ref.Registry, ref.Repository, sha256Hex)
+}
+
+func (h *httpRegistryClient) authenticate(req *http.Request, auth *RegistryAuthConfig) {
+	if auth == nil {
+		return
+	}
+	switch {
+	case auth.Token != "":
+		req.Header.Set("Authorization", "Bearer "+auth.Token)
+	case auth.Username != "":
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+}
+
+func (h *httpRegistryClient) ResolveManifest(ctx context.Context, ref *PluginReference, auth *RegistryAuthConfig) (*PluginManifest, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.manifestURL(ref), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.hashicorp.vault.plugin.manifest.v1+json")
+	h.authenticate(req, auth)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry returned %s fetching manifest", resp.Status)
+	}
+
+	manifest := new(PluginManifest)
+	if err := json.NewDecoder(resp.Body).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+func (h *httpRegistryClient) FetchBlob(ctx context.Context, ref *PluginReference, sha256Hex string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.blobURL(ref, sha256Hex), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("registry returned %s fetching blob", resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+func (h *httpRegistryClient) PushManifest(ctx context.Context, ref *PluginReference, auth *RegistryAuthConfig, manifest *PluginManifest) error {
+	buf, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, h.manifestURL(ref), bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.hashicorp.vault.plugin.manifest.v1+json")
+	h.authenticate(req, auth)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry returned %s pushing manifest", resp.Status)
+	}
+
+	return nil
+}
+
+func (h *httpRegistryClient) PushBlob(ctx context.Context, ref *PluginReference, auth *RegistryAuthConfig, sha256Hex string, size int64, r io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, h.blobURL(ref, sha256Hex), r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	h.authenticate(req, auth)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry returned %s pushing blob", resp.Status)
+	}
+
+	return nil
+}
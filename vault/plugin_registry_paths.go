@@ -0,0 +1,243 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// PluginRegistryPaths returns the sys backend routes that expose
+// PluginCatalog's registry-based Pull/Push/Privileges/Upgrade to operators
+// over Vault's HTTP API. Without these, a registry reference can only be
+// resolved by code running inside Vault itself; registerSystemPaths (in
+// logical_system.go) appends the result onto the rest of the sys backend's
+// paths.
+func PluginRegistryPaths(catalog *PluginCatalog) []*framework.Path {
+	h := &pluginRegistryHandler{catalog: catalog}
+
+	return []*framework.Path{
+		{
+			Pattern: "plugins/registry/pull/(?P<type>auth|database|secret)/(?P<reference>.+)",
+			Fields: map[string]*framework.FieldSchema{
+				"type": {
+					Type:        framework.TypeString,
+					Description: "Type of the plugin being pulled.",
+				},
+				"reference": {
+					Type:        framework.TypeString,
+					Description: "Registry reference to pull, e.g. registry.example.com/vault-plugins/mysql:v1.2.3.",
+				},
+				"username": {
+					Type:        framework.TypeString,
+					Description: "Username for registry authentication, if required.",
+				},
+				"password": {
+					Type:        framework.TypeString,
+					Description: "Password for registry authentication, if required.",
+				},
+				"token": {
+					Type:        framework.TypeString,
+					Description: "Bearer token for registry authentication, if required.",
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: h.handlePull,
+					Summary:  "Pull a plugin binary from a registry and register it in the catalog.",
+				},
+			},
+		},
+		{
+			Pattern: "plugins/registry/upgrade/(?P<type>auth|database|secret)/(?P<reference>.+)",
+			Fields: map[string]*framework.FieldSchema{
+				"type": {
+					Type:        framework.TypeString,
+					Description: "Type of the plugin being upgraded.",
+				},
+				"reference": {
+					Type:        framework.TypeString,
+					Description: "Registry reference to upgrade to.",
+				},
+				"username": {
+					Type:        framework.TypeString,
+					Description: "Username for registry authentication, if required.",
+				},
+				"password": {
+					Type:        framework.TypeString,
+					Description: "Password for registry authentication, if required.",
+				},
+				"token": {
+					Type:        framework.TypeString,
+					Description: "Bearer token for registry authentication, if required.",
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: h.handleUpgrade,
+					Summary:  "Repoint an already-registered plugin's symlink at a newer registry reference.",
+				},
+			},
+		},
+		{
+			Pattern: "plugins/registry/privileges/(?P<reference>.+)",
+			Fields: map[string]*framework.FieldSchema{
+				"reference": {
+					Type:        framework.TypeString,
+					Description: "Registry reference to inspect.",
+				},
+				"username": {
+					Type:        framework.TypeString,
+					Description: "Username for registry authentication, if required.",
+				},
+				"password": {
+					Type:        framework.TypeString,
+					Description: "Password for registry authentication, if required.",
+				},
+				"token": {
+					Type:        framework.TypeString,
+					Description: "Bearer token for registry authentication, if required.",
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: h.handlePrivileges,
+					Summary:  "Resolve a registry reference's manifest and return its declared capabilities and env, without downloading the plugin binary.",
+				},
+			},
+		},
+		{
+			Pattern: "plugins/registry/push/(?P<reference>.+)",
+			Fields: map[string]*framework.FieldSchema{
+				"reference": {
+					Type:        framework.TypeString,
+					Description: "Registry reference to push to.",
+				},
+				"path": {
+					Type:        framework.TypeString,
+					Description: "Path of the plugin binary to push, relative to the configured plugin directory.",
+				},
+				"version": {
+					Type:        framework.TypeString,
+					Description: "Semver version to record in the pushed manifest.",
+				},
+				"username": {
+					Type:        framework.TypeString,
+					Description: "Username for registry authentication, if required.",
+				},
+				"password": {
+					Type:        framework.TypeString,
+					Description: "Password for registry authentication, if required.",
+				},
+				"token": {
+					Type:        framework.TypeString,
+					Description: "Bearer token for registry authentication, if required.",
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: h.handlePush,
+					Summary:  "Push a local plugin binary and its manifest to a registry.",
+				},
+			},
+		},
+	}
+}
+
+// pluginRegistryHandler adapts PluginCatalog's registry methods to
+// framework.Path callbacks.
+type pluginRegistryHandler struct {
+	catalog *PluginCatalog
+}
+
+// pluginTypeFromPathSegment maps the "auth|database|secret" path segment
+// these routes accept onto a consts.PluginType, mirroring the type names
+// already used elsewhere in the catalog's storage keys.
+func pluginTypeFromPathSegment(s string) (consts.PluginType, error) {
+	switch s {
+	case "auth":
+		return consts.PluginTypeCredential, nil
+	case "database":
+		return consts.PluginTypeDatabase, nil
+	case "secret":
+		return consts.PluginTypeSecrets, nil
+	default:
+		return consts.PluginTypeUnknown, fmt.Errorf("unsupported plugin type %q", s)
+	}
+}
+
+func authConfigFromFieldData(d *framework.FieldData) *RegistryAuthConfig {
+	auth := &RegistryAuthConfig{
+		Username: d.Get("username").(string),
+		Password: d.Get("password").(string),
+		Token:    d.Get("token").(string),
+	}
+	if auth.Username == "" && auth.Token == "" {
+		return nil
+	}
+	return auth
+}
+
+func (h *pluginRegistryHandler) handlePull(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	pluginType, err := pluginTypeFromPathSegment(d.Get("type").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	reference := d.Get("reference").(string)
+	if err := h.catalog.Pull(ctx, reference, pluginType, authConfigFromFieldData(d)); err != nil {
+		return nil, fmt.Errorf("failed to pull plugin %q: %w", reference, err)
+	}
+
+	return nil, nil
+}
+
+func (h *pluginRegistryHandler) handleUpgrade(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	pluginType, err := pluginTypeFromPathSegment(d.Get("type").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	reference := d.Get("reference").(string)
+	if err := h.catalog.Upgrade(ctx, reference, pluginType, authConfigFromFieldData(d)); err != nil {
+		return nil, fmt.Errorf("failed to upgrade plugin %q: %w", reference, err)
+	}
+
+	return nil, nil
+}
+
+func (h *pluginRegistryHandler) handlePrivileges(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	reference := d.Get("reference").(string)
+	privileges, err := h.catalog.Privileges(ctx, reference, authConfigFromFieldData(d))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve privileges for %q: %w", reference, err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"name":         privileges.Name,
+			"version":      privileges.Version,
+			"capabilities": privileges.Capabilities,
+			"env":          privileges.Env,
+		},
+	}, nil
+}
+
+func (h *pluginRegistryHandler) handlePush(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	reference := d.Get("reference").(string)
+	localPath := d.Get("path").(string)
+	if localPath == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	manifest := &PluginManifest{
+		Version: d.Get("version").(string),
+	}
+	if err := h.catalog.Push(ctx, reference, localPath, manifest, authConfigFromFieldData(d)); err != nil {
+		return nil, fmt.Errorf("failed to push plugin to %q: %w", reference, err)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,106 @@
+package vault
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+)
+
+type fakeRegistryClient struct {
+	manifest *PluginManifest
+	blob     string
+}
+
+func (f *fakeRegistryClient) ResolveManifest(ctx context.Context, ref *PluginReference, auth *RegistryAuthConfig) (*PluginManifest, error) {
+	return f.manifest, nil
+}
+
+func (f *fakeRegistryClient) FetchBlob(ctx context.Context, ref *PluginReference, sha256Hex string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(f.blob)), nil
+}
+
+func (f *fakeRegistryClient) PushManifest(ctx context.Context, ref *PluginReference, auth *RegistryAuthConfig, manifest *PluginManifest) error {
+	return nil
+}
+
+func (f *fakeRegistryClient) PushBlob(ctx context.Context, ref *PluginReference, auth *RegistryAuthConfig, sha256Hex string, size int64, r io.Reader) error {
+	return nil
+}
+
+func TestPluginCatalog_Pull_RejectsUnsafeManifest(t *testing.T) {
+	validSha := strings.Repeat("a", 64)
+
+	cases := []struct {
+		name     string
+		manifest *PluginManifest
+	}{
+		{
+			name:     "name contains parent reference",
+			manifest: &PluginManifest{Name: "../../etc/cron.d/evil", Sha256: validSha},
+		},
+		{
+			name:     "sha256 too short",
+			manifest: &PluginManifest{Name: "mysql-plugin", Sha256: "abc"},
+		},
+		{
+			name:     "sha256 contains non-hex characters",
+			manifest: &PluginManifest{Name: "mysql-plugin", Sha256: strings.Repeat("g", 64)},
+		},
+		{
+			name:     "sha256 contains path traversal",
+			manifest: &PluginManifest{Name: "mysql-plugin", Sha256: "../../../../etc/passwd000000000000000000000000000000"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			c := &PluginCatalog{
+				directory:              dir,
+				logger:                 log.NewNullLogger(),
+				registryClientOverride: &fakeRegistryClient{manifest: tc.manifest, blob: "binary"},
+			}
+
+			err := c.Pull(context.Background(), "registry.example.com/repo/mysql-plugin:v1", consts.PluginTypeDatabase, nil)
+			if err == nil {
+				t.Fatalf("expected Pull to reject unsafe manifest, got nil error")
+			}
+		})
+	}
+}
+
+func TestIsValidSha256Hex(t *testing.T) {
+	cases := []struct {
+		digest string
+		valid  bool
+	}{
+		{strings.Repeat("a", 64), true},
+		{strings.Repeat("A", 64), false},
+		{strings.Repeat("a", 63), false},
+		{strings.Repeat("a", 65), false},
+		{"../../../../etc/passwd", false},
+		{"", false},
+	}
+
+	for _, tc := range cases {
+		if got := isValidSha256Hex(tc.digest); got != tc.valid {
+			t.Errorf("isValidSha256Hex(%q) = %v, want %v", tc.digest, got, tc.valid)
+		}
+	}
+}
+
+func TestBlobStore_WriteRejectsDigestMismatch(t *testing.T) {
+	store := newBlobStore(t.TempDir())
+
+	want := strings.Repeat("b", 64)
+	if err := store.write(want, strings.NewReader("not the right content for this digest")); err == nil {
+		t.Fatal("expected write to reject content that doesn't match the declared digest")
+	}
+	if store.has(want) {
+		t.Fatal("blob store should not retain content that failed digest verification")
+	}
+}
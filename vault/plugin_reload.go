@@ -0,0 +1,85 @@
+package vault
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/helper/pluginutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func hashPluginData(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Reload re-evaluates every registered plugin's FileData source and
+// restarts the multiplexed client for any plugin whose data has changed
+// since it was last spawned. FixedData plugins are untouched, since their
+// config can only change by re-registering.
+//
+// This gives operators a way to rotate plugin configuration, such as a
+// database credentials template, without tearing down and re-registering
+// the plugin.
+func (c *PluginCatalog) Reload(ctx context.Context) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	keys, err := logical.CollectKeys(ctx, c.catalogView)
+	if err != nil {
+		return fmt.Errorf("failed to list plugin catalog entries: %w", err)
+	}
+
+	var retErr *multierror.Error
+	for _, key := range keys {
+		out, err := c.catalogView.Get(ctx, key)
+		if err != nil || out == nil {
+			continue
+		}
+
+		runner := new(pluginutil.PluginRunner)
+		if err := jsonutil.DecodeJSON(out.Value, runner); err != nil {
+			retErr = multierror.Append(retErr, fmt.Errorf("failed to decode plugin entry %q: %w", key, err))
+			continue
+		}
+
+		if DataSourceKind(runner.DataSourceKind) != DataSourceFile {
+			continue
+		}
+
+		mpc, ok := c.multiplexedClients[runner.Name]
+		if !ok || mpc.client == nil {
+			// Nothing running for this plugin; the next getPluginClient
+			// call will pick up the current file contents anyway.
+			continue
+		}
+
+		dataSource, err := dataSourceFromRunner(runner)
+		if err != nil {
+			retErr = multierror.Append(retErr, fmt.Errorf("failed to resolve data source for %q: %w", runner.Name, err))
+			continue
+		}
+
+		data, err := dataSource.Get(ctx)
+		if err != nil {
+			retErr = multierror.Append(retErr, fmt.Errorf("failed to read updated config for %q: %w", runner.Name, err))
+			continue
+		}
+
+		if hashPluginData(data) == mpc.dataHash {
+			continue
+		}
+
+		c.logger.Info("plugin config changed, restarting multiplexed client", "plugin", runner.Name)
+
+		if err := c.restartPlugin(ctx, runner.Name); err != nil {
+			retErr = multierror.Append(retErr, fmt.Errorf("failed to restart plugin %q after config change: %w", runner.Name, err))
+		}
+	}
+
+	return retErr.ErrorOrNil()
+}
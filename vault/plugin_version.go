@@ -0,0 +1,103 @@
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hashicorp/go-version"
+)
+
+// ErrPluginChecksumMismatch is returned when the binary on disk no longer
+// matches the sha256 recorded in the catalog at registration time, whether
+// because it was tampered with or swapped out from under Vault.
+var ErrPluginChecksumMismatch = errors.New("plugin binary does not match its stored sha256, refusing to run it")
+
+// ErrPluginVersionConstraint is returned when a plugin's recorded version
+// falls outside the min_version/max_version bounds configured for it.
+var ErrPluginVersionConstraint = errors.New("plugin version does not satisfy configured version constraint")
+
+// hashFile returns the hex-encoded sha256 digest of the file at path,
+// following symlinks.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyChecksum hashes the binary at commandFull and compares it against
+// want. If want is empty (no sha256 was ever recorded for this plugin),
+// verification is skipped, matching prior behavior where the sha256 was
+// stored but never actually checked against the binary at run time.
+func verifyChecksum(commandFull string, want []byte) error {
+	if len(want) == 0 {
+		return nil
+	}
+
+	got, err := hashFile(commandFull)
+	if err != nil {
+		return fmt.Errorf("failed to hash plugin binary: %w", err)
+	}
+
+	if got != hex.EncodeToString(want) {
+		return fmt.Errorf("%w: expected %s, got %s", ErrPluginChecksumMismatch, hex.EncodeToString(want), got)
+	}
+
+	return nil
+}
+
+// checkVersionConstraint parses reportedVersion — the plugin's informational
+// Version, as recorded in the catalog at registration time (explicitly via
+// config, or from a registry manifest for a Pull'd plugin) — and verifies it
+// falls within [minVersion, maxVersion]. Either bound may be empty to leave
+// it unconstrained. If a constraint is configured but reportedVersion is
+// empty, that's an error rather than a pass: a plugin with no recorded
+// version can't be verified to satisfy one, and letting it through would
+// defeat the constraint entirely.
+func checkVersionConstraint(reportedVersion, minVersion, maxVersion string) error {
+	if minVersion == "" && maxVersion == "" {
+		return nil
+	}
+	if reportedVersion == "" {
+		return fmt.Errorf("%w: plugin did not report a version, but min_version/max_version is configured", ErrPluginVersionConstraint)
+	}
+
+	reported, err := version.NewVersion(reportedVersion)
+	if err != nil {
+		return fmt.Errorf("could not parse plugin-reported version %q: %w", reportedVersion, err)
+	}
+
+	if minVersion != "" {
+		min, err := version.NewVersion(minVersion)
+		if err != nil {
+			return fmt.Errorf("could not parse configured min_version %q: %w", minVersion, err)
+		}
+		if reported.LessThan(min) {
+			return fmt.Errorf("%w: plugin reports %s, which is below min_version %s", ErrPluginVersionConstraint, reportedVersion, minVersion)
+		}
+	}
+
+	if maxVersion != "" {
+		max, err := version.NewVersion(maxVersion)
+		if err != nil {
+			return fmt.Errorf("could not parse configured max_version %q: %w", maxVersion, err)
+		}
+		if reported.GreaterThan(max) {
+			return fmt.Errorf("%w: plugin reports %s, which is above max_version %s", ErrPluginVersionConstraint, reportedVersion, maxVersion)
+		}
+	}
+
+	return nil
+}
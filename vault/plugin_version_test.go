@@ -0,0 +1,69 @@
+package vault
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugin-binary")
+	if err := os.WriteFile(path, []byte("plugin contents"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := hashFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantBytes, err := hex.DecodeString(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verifyChecksum(path, wantBytes); err != nil {
+		t.Fatalf("verifyChecksum with correct digest should pass, got: %v", err)
+	}
+
+	if err := verifyChecksum(path, nil); err != nil {
+		t.Fatalf("verifyChecksum with no recorded digest should be skipped, got: %v", err)
+	}
+
+	badBytes, err := hex.DecodeString(strings.Repeat("0", 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyChecksum(path, badBytes); err == nil {
+		t.Fatal("verifyChecksum with a mismatched digest should fail")
+	}
+}
+
+func TestCheckVersionConstraint(t *testing.T) {
+	cases := []struct {
+		name                      string
+		reportedVersion, min, max string
+		wantErr                   bool
+	}{
+		{name: "no constraint configured, no version reported", reportedVersion: "", min: "", max: ""},
+		{name: "within bounds", reportedVersion: "1.2.0", min: "1.0.0", max: "2.0.0"},
+		{name: "below min", reportedVersion: "0.9.0", min: "1.0.0", max: "2.0.0", wantErr: true},
+		{name: "above max", reportedVersion: "2.1.0", min: "1.0.0", max: "2.0.0", wantErr: true},
+		{name: "constraint configured but no version reported", reportedVersion: "", min: "1.0.0", max: "", wantErr: true},
+		{name: "unparseable reported version", reportedVersion: "not-a-version", min: "1.0.0", max: "", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkVersionConstraint(tc.reportedVersion, tc.min, tc.max)
+			if tc.wantErr && err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+		})
+	}
+}